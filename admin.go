@@ -0,0 +1,129 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	adminTokenHeader   = "X-Admin-Token"
+	adminSessionCookie = "admin_session"
+
+	// adminActorKey 通过管理员鉴权的请求，审计日志中记为 admin
+	adminActorKey = "admin_actor"
+)
+
+// adminToken 管理员令牌，未配置时管理接口和管理后台整体关闭
+func adminToken() string {
+	return os.Getenv("ADMIN_TOKEN")
+}
+
+// validAdminToken 常量时间比较，避免时序攻击
+func validAdminToken(got string) bool {
+	token := adminToken()
+	return token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// adminAuth 管理接口鉴权：请求头 X-Admin-Token 必须与环境变量 ADMIN_TOKEN 一致
+// 未配置 ADMIN_TOKEN 时管理接口整体关闭
+func adminAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if adminToken() == "" {
+			abortJSON(c, http.StatusForbidden, gin.H{"error": "admin api disabled"})
+			return
+		}
+
+		if !validAdminToken(c.GetHeader(adminTokenHeader)) {
+			abortJSON(c, http.StatusUnauthorized, gin.H{"error": "invalid admin token"})
+			return
+		}
+
+		c.Set(adminActorKey, true)
+		c.Next()
+	}
+}
+
+// adminPageAuth 管理后台页面鉴权：登录后会话保存在 admin_session Cookie 中，未登录跳转到登录页
+func adminPageAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if adminToken() == "" {
+			c.Abort()
+			c.String(http.StatusForbidden, "admin dashboard disabled: ADMIN_TOKEN not configured")
+			return
+		}
+
+		session, _ := c.Cookie(adminSessionCookie)
+		if !validAdminSession(session, time.Now()) {
+			c.Abort()
+			c.Redirect(http.StatusSeeOther, "/admin/login")
+			return
+		}
+
+		c.Set(adminActorKey, true)
+		c.Next()
+	}
+}
+
+// newAdminSession 生成会话值 "<过期时间戳>.<签名>"，签名由 ADMIN_TOKEN 派生，
+// Cookie 泄露时不会暴露令牌本身，且过期后自动失效
+func newAdminSession(expiry time.Time) string {
+	exp := strconv.FormatInt(expiry.Unix(), 10)
+	return exp + "." + hex.EncodeToString(adminSessionMAC(exp))
+}
+
+// validAdminSession 校验会话签名和有效期
+func validAdminSession(session string, now time.Time) bool {
+	if adminToken() == "" {
+		return false
+	}
+
+	exp, sig, ok := strings.Cut(session, ".")
+	if !ok {
+		return false
+	}
+	expiry, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || now.Unix() >= expiry {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(got, adminSessionMAC(exp))
+}
+
+func adminSessionMAC(exp string) []byte {
+	mac := hmac.New(sha256.New, []byte(adminToken()))
+	mac.Write([]byte("admin-session:" + exp))
+	return mac.Sum(nil)
+}
+
+// secureCookie 是否设置 Secure：ADMIN_COOKIE_SECURE=true、本进程终止TLS或前置代理声明 https
+func secureCookie(c *gin.Context) bool {
+	if secure, err := strconv.ParseBool(os.Getenv("ADMIN_COOKIE_SECURE")); err == nil && secure {
+		return true
+	}
+	return c.Request.TLS != nil || requestScheme(c) == "https"
+}
+
+// setAdminCookie 写入或清除（maxAge<0）登录Cookie，SameSite=Strict 防止跨站提交表单
+func setAdminCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     adminSessionCookie,
+		Value:    value,
+		Path:     "/admin",
+		MaxAge:   maxAge,
+		Secure:   secureCookie(c),
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdminSession(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "admin-secret")
+	now := time.Now()
+	session := newAdminSession(now.Add(time.Hour))
+
+	if strings.Contains(session, "admin-secret") {
+		t.Fatal("session must not contain the admin token")
+	}
+	if !validAdminSession(session, now) {
+		t.Fatal("fresh session should be valid")
+	}
+	if validAdminSession(session, now.Add(2*time.Hour)) {
+		t.Error("expired session should be rejected")
+	}
+
+	exp, sig, _ := strings.Cut(session, ".")
+	expiry, _ := strconv.ParseInt(exp, 10, 64)
+	extended := strconv.FormatInt(expiry+3600, 10)
+	if validAdminSession(extended+"."+sig, now) {
+		t.Error("session with extended expiry should be rejected")
+	}
+	for _, bad := range []string{"", "garbage", exp + ".zz", exp} {
+		if validAdminSession(bad, now) {
+			t.Errorf("malformed session %q should be rejected", bad)
+		}
+	}
+
+	t.Setenv("ADMIN_TOKEN", "rotated-secret")
+	if validAdminSession(session, now) {
+		t.Error("rotating ADMIN_TOKEN should invalidate existing sessions")
+	}
+}
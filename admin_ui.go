@@ -0,0 +1,279 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// 管理后台（服务端渲染）：用户查询/编辑、缓存状态、审计日志
+
+const (
+	adminSessionMaxAge = 12 * 60 * 60 // 登录有效期：12小时
+	adminCacheScanMax  = 100          // 缓存页最多展示的key数量
+	adminAuditMax      = 100          // 审计页最多展示的记录数量
+
+	// adminCachePrefix 管理后台只允许查看/删除用户缓存，
+	// nonce、审计日志、废弃接口统计等key不在此列，避免被误删或泄露
+	adminCachePrefix = "user:"
+)
+
+// CacheStatus 单个缓存key的状态
+type CacheStatus struct {
+	Key    string
+	Exists bool
+	TTL    time.Duration // -1 表示永不过期
+	Value  string
+}
+
+// adminError 渲染错误页
+func adminError(c *gin.Context, code int, err error) {
+	c.HTML(code, "error.tmpl", gin.H{"Title": "出错了", "Code": code, "Error": err.Error()})
+}
+
+// adminLoginPage 登录页
+func adminLoginPage(c *gin.Context) {
+	c.HTML(http.StatusOK, "login.tmpl", gin.H{"Title": "登录"})
+}
+
+// adminLogin 校验令牌并写入登录Cookie
+func adminLogin(c *gin.Context) {
+	token := c.PostForm("token")
+	if !validAdminToken(token) {
+		c.HTML(http.StatusUnauthorized, "login.tmpl", gin.H{"Title": "登录", "Error": "令牌错误或管理后台未启用"})
+		return
+	}
+
+	expiry := time.Now().Add(adminSessionMaxAge * time.Second)
+	setAdminCookie(c, newAdminSession(expiry), adminSessionMaxAge)
+	c.Redirect(http.StatusSeeOther, "/admin/users")
+}
+
+// adminLogout 清除登录Cookie
+func adminLogout(c *gin.Context) {
+	setAdminCookie(c, "", -1)
+	c.Redirect(http.StatusSeeOther, "/admin/login")
+}
+
+// adminUsersPage 用户列表，支持按用户名/邮箱模糊搜索和分页
+func adminUsersPage(c *gin.Context) {
+	p, paged, err := parsePagination(c)
+	if err != nil {
+		adminError(c, http.StatusBadRequest, err)
+		return
+	}
+	if !paged {
+		p = pagination{Page: 1, PageSize: defaultPageSize}
+	}
+
+	keyword := strings.TrimSpace(c.Query("q"))
+	query := func() *gorm.DB {
+		tx := db.WithContext(c.Request.Context()).Model(&User{})
+		if keyword != "" {
+			like := "%" + keyword + "%"
+			tx = tx.Where("name LIKE ? OR email LIKE ?", like, like)
+		}
+		return tx
+	}
+
+	if err := query().Count(&p.Total).Error; err != nil {
+		adminError(c, http.StatusInternalServerError, err)
+		return
+	}
+	var users []User
+	if !p.pastEnd() {
+		if err := query().Order("id").Offset(p.offset()).Limit(p.PageSize).Find(&users).Error; err != nil {
+			adminError(c, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	pageURL := func(page int) string {
+		v := url.Values{}
+		if keyword != "" {
+			v.Set("q", keyword)
+		}
+		v.Set("page", strconv.Itoa(page))
+		v.Set("page_size", strconv.Itoa(p.PageSize))
+		return "/admin/users?" + v.Encode()
+	}
+	data := gin.H{
+		"Title":    "用户",
+		"Users":    users,
+		"Query":    keyword,
+		"Page":     p,
+		"LastPage": p.lastPage(),
+	}
+	if p.Page > 1 {
+		data["PrevURL"] = pageURL(p.Page - 1)
+	}
+	if p.Page < p.lastPage() {
+		data["NextURL"] = pageURL(p.Page + 1)
+	}
+
+	c.HTML(http.StatusOK, "users.tmpl", data)
+}
+
+// adminUserPage 用户详情：基本信息、编辑表单和对应缓存状态
+func adminUserPage(c *gin.Context) {
+	id := c.Param("id")
+
+	var user User
+	if err := db.WithContext(c.Request.Context()).Where("id = ?", id).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			adminError(c, http.StatusNotFound, fmt.Errorf("user %s not found", id))
+			return
+		}
+		adminError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	cache, err := lookupCacheStatus(c, fmt.Sprintf("user:%s", id))
+	if err != nil {
+		adminError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.HTML(http.StatusOK, "user.tmpl", gin.H{
+		"Title": fmt.Sprintf("用户 #%d", user.ID),
+		"User":  user,
+		"Cache": cache,
+		"Saved": c.Query("saved") != "",
+	})
+}
+
+// adminUpdateUser 保存编辑后的用户信息（更新MySQL，删除Redis缓存）
+func adminUpdateUser(c *gin.Context) {
+	id := c.Param("id")
+	cacheKey := fmt.Sprintf("user:%s", id)
+	reqCtx := c.Request.Context()
+
+	name := strings.TrimSpace(c.PostForm("name"))
+	email := strings.TrimSpace(c.PostForm("email"))
+	if name == "" || email == "" {
+		adminError(c, http.StatusBadRequest, errors.New("用户名和邮箱不能为空"))
+		return
+	}
+
+	update := User{Name: name, Email: email, UpdateAt: time.Now()}
+	if err := db.WithContext(reqCtx).Model(&User{}).Where("id = ?", id).Updates(update).Error; err != nil {
+		adminError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := rdb.Del(reqCtx, cacheKey).Err(); err != nil {
+		fmt.Printf("redis del failed: %v\n", err)
+	}
+
+	recordAudit(c, "user.update", cacheKey, fmt.Sprintf("name=%s email=%s", name, email))
+
+	c.Redirect(http.StatusSeeOther, fmt.Sprintf("/admin/users/%s?saved=1", id))
+}
+
+// adminCachePage 按模式扫描缓存key并展示状态
+func adminCachePage(c *gin.Context) {
+	pattern := c.DefaultQuery("pattern", adminCachePrefix+"*")
+	if !strings.HasPrefix(pattern, adminCachePrefix) {
+		adminError(c, http.StatusBadRequest, fmt.Errorf("pattern 必须以 %s 开头", adminCachePrefix))
+		return
+	}
+	reqCtx := c.Request.Context()
+
+	var keys []string
+	iter := rdb.Scan(reqCtx, 0, pattern, adminCacheScanMax).Iterator()
+	for iter.Next(reqCtx) && len(keys) < adminCacheScanMax {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		adminError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	statuses := make([]CacheStatus, 0, len(keys))
+	for _, key := range keys {
+		status, err := lookupCacheStatus(c, key)
+		if err != nil {
+			adminError(c, http.StatusInternalServerError, err)
+			return
+		}
+		statuses = append(statuses, status)
+	}
+
+	c.HTML(http.StatusOK, "cache.tmpl", gin.H{
+		"Title":   "缓存",
+		"Pattern": pattern,
+		"Entries": statuses,
+		"Limit":   adminCacheScanMax,
+	})
+}
+
+// adminEvictCache 删除指定缓存key
+func adminEvictCache(c *gin.Context) {
+	key := c.PostForm("key")
+	if !strings.HasPrefix(key, adminCachePrefix) || key == adminCachePrefix {
+		adminError(c, http.StatusBadRequest, fmt.Errorf("只能删除 %s 开头的用户缓存", adminCachePrefix))
+		return
+	}
+
+	if err := rdb.Del(c.Request.Context(), key).Err(); err != nil {
+		adminError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	recordAudit(c, "cache.evict", key, "")
+
+	redirect := c.PostForm("redirect")
+	if !strings.HasPrefix(redirect, "/admin/") {
+		redirect = "/admin/cache"
+	}
+	c.Redirect(http.StatusSeeOther, redirect)
+}
+
+// adminAuditPage 最近的审计记录
+func adminAuditPage(c *gin.Context) {
+	events, err := recentAuditEvents(c, adminAuditMax)
+	if err != nil {
+		adminError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.HTML(http.StatusOK, "audit.tmpl", gin.H{
+		"Title":  "审计日志",
+		"Events": events,
+		"Limit":  adminAuditMax,
+	})
+}
+
+// lookupCacheStatus 查询缓存key是否存在、剩余TTL及当前值
+func lookupCacheStatus(c *gin.Context, key string) (CacheStatus, error) {
+	status := CacheStatus{Key: key}
+	reqCtx := c.Request.Context()
+
+	value, err := rdb.Get(reqCtx, key).Result()
+	switch {
+	case errors.Is(err, redis.Nil):
+		return status, nil
+	case err != nil && !strings.HasPrefix(err.Error(), "WRONGTYPE"):
+		return status, err
+	case err != nil:
+		value = "(非字符串类型)"
+	}
+
+	ttl, err := rdb.TTL(reqCtx, key).Result()
+	if err != nil {
+		return status, err
+	}
+
+	status.Exists = true
+	status.TTL = ttl
+	status.Value = value
+	return status, nil
+}
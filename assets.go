@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// 管理后台的模板和静态资源默认编译进二进制（embed.FS），部署时无需附带目录；
+// ASSETS_DEV=true 时改为每次请求从磁盘读取（根目录由 ASSETS_DIR 指定，默认当前工作目录），修改模板/样式后刷新即可生效
+
+//go:embed templates static
+var embeddedAssets embed.FS
+
+const (
+	adminTemplatePattern = "templates/admin/*.tmpl"
+	adminStaticDir       = "static/admin"
+
+	// staticMaxAge 嵌入资源的浏览器缓存时间，过期后凭 ETag 协商
+	staticMaxAge = time.Hour
+)
+
+var (
+	assetsFS  fs.FS = embeddedAssets
+	assetsDev bool
+
+	// assetETags 嵌入资源内容不变，ETag 只需计算一次
+	assetETags sync.Map
+)
+
+// initAssets 根据 ASSETS_DEV 选择资源来源，开发模式下确认 ASSETS_DIR 下存在模板和静态资源目录
+func initAssets() error {
+	dev, err := strconv.ParseBool(os.Getenv("ASSETS_DEV"))
+	if err != nil && os.Getenv("ASSETS_DEV") != "" {
+		return fmt.Errorf("invalid ASSETS_DEV: %v", err)
+	}
+
+	assetsDev = dev
+	if !assetsDev {
+		assetsFS = embeddedAssets
+		return nil
+	}
+
+	dir := os.Getenv("ASSETS_DIR")
+	if dir == "" {
+		dir = "."
+	}
+	diskFS := os.DirFS(dir)
+	for _, sub := range []string{path.Dir(adminTemplatePattern), adminStaticDir} {
+		if info, err := fs.Stat(diskFS, sub); err != nil || !info.IsDir() {
+			return fmt.Errorf("ASSETS_DEV: %s not found under ASSETS_DIR %q (set ASSETS_DIR to the repository root)", sub, dir)
+		}
+	}
+	assetsFS = diskFS
+	return nil
+}
+
+// loadAdminTemplates 加载管理后台模板；开发模式下启动时先完整解析一次，
+// 路径或语法错误在启动阶段暴露，之后每次渲染重新解析
+func loadAdminTemplates(r *gin.Engine) error {
+	tmpl, err := template.New("").Funcs(r.FuncMap).ParseFS(assetsFS, adminTemplatePattern)
+	if err != nil {
+		return fmt.Errorf("parse admin templates failed: %v", err)
+	}
+
+	if assetsDev {
+		r.HTMLRender = render.HTMLDebug{
+			FileSystem: http.FS(assetsFS),
+			Patterns:   []string{adminTemplatePattern},
+			FuncMap:    r.FuncMap,
+		}
+		return nil
+	}
+	r.HTMLRender = render.HTMLProduction{Template: tmpl}
+	return nil
+}
+
+// serveAdminStatic 输出管理后台静态资源，带 ETag 和 Cache-Control，支持 If-None-Match 返回304
+func serveAdminStatic(c *gin.Context) {
+	name := path.Join(adminStaticDir, path.Clean("/"+c.Param("filepath")))
+
+	// 不存在、目录等无法读取的路径统一返回404
+	data, err := fs.ReadFile(assetsFS, name)
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Header("ETag", assetETag(name, data))
+	if assetsDev {
+		c.Header("Cache-Control", "no-cache")
+	} else {
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(staticMaxAge.Seconds())))
+	}
+
+	// 嵌入文件没有修改时间，仅依赖 ETag 协商缓存
+	http.ServeContent(c.Writer, c.Request, name, time.Time{}, bytes.NewReader(data))
+}
+
+// assetETag 基于内容哈希的强 ETag
+func assetETag(name string, data []byte) string {
+	if !assetsDev {
+		if etag, ok := assetETags.Load(name); ok {
+			return etag.(string)
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	if !assetsDev {
+		assetETags.Store(name, etag)
+	}
+	return etag
+}
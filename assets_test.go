@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// useAssetsEnv 设置资源相关环境变量并重新初始化，测试结束后恢复为嵌入资源
+func useAssetsEnv(t *testing.T, dev, dir string) error {
+	t.Helper()
+	t.Setenv("ASSETS_DEV", dev)
+	t.Setenv("ASSETS_DIR", dir)
+	t.Cleanup(func() {
+		assetsFS, assetsDev = embeddedAssets, false
+	})
+	return initAssets()
+}
+
+func TestAssetsDevDir(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("missing directory fails at startup", func(t *testing.T) {
+		if err := useAssetsEnv(t, "true", t.TempDir()); err == nil {
+			t.Fatal("expected error for ASSETS_DIR without templates")
+		}
+	})
+
+	t.Run("broken template fails at startup", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, sub := range []string{"templates/admin", "static/admin"} {
+			if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(dir, "templates/admin/login.tmpl"), []byte(`{{define "login.tmpl"}}{{.Title`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := useAssetsEnv(t, "true", dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := loadAdminTemplates(gin.New()); err == nil {
+			t.Fatal("expected parse error")
+		}
+	})
+
+	for _, tc := range []struct{ name, dev, dir string }{
+		{"embedded", "false", ""},
+		{"dev from repository root", "true", "."},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := useAssetsEnv(t, tc.dev, tc.dir); err != nil {
+				t.Fatal(err)
+			}
+			r := gin.New()
+			if err := loadAdminTemplates(r); err != nil {
+				t.Fatal(err)
+			}
+			r.GET("/admin/login", adminLoginPage)
+			r.GET("/admin/static/*filepath", serveAdminStatic)
+
+			for _, target := range []string{"/admin/login", "/admin/static/admin.css"} {
+				if w := serve(r, httptest.NewRequest(http.MethodGet, target, nil)); w.Code != http.StatusOK {
+					t.Errorf("%s status = %d", target, w.Code)
+				}
+			}
+		})
+	}
+}
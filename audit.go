@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 审计日志：记录用户数据的写操作，保存在Redis列表中，仅保留最近 auditMaxEvents 条
+
+const (
+	auditEventsKey = "audit:events"
+	auditMaxEvents = 1000
+)
+
+// AuditEvent 单条审计记录
+type AuditEvent struct {
+	Time   time.Time `json:"time"`
+	Actor  string    `json:"actor"`  // admin（管理后台/管理接口）或 api
+	Action string    `json:"action"` // user.create / user.update / user.delete
+	Target string    `json:"target"`
+	Detail string    `json:"detail"`
+	IP     string    `json:"ip"`
+}
+
+// recordAudit 写入审计记录（仅打印日志，不影响接口返回）
+func recordAudit(c *gin.Context, action, target, detail string) {
+	actor := "api"
+	if c.GetBool(adminActorKey) {
+		actor = "admin"
+	}
+
+	data, err := jsonAPI.Marshal(AuditEvent{
+		Time:   time.Now(),
+		Actor:  actor,
+		Action: action,
+		Target: target,
+		Detail: detail,
+		IP:     c.ClientIP(),
+	})
+	if err != nil {
+		fmt.Printf("audit encode failed: %v\n", err)
+		return
+	}
+
+	reqCtx := c.Request.Context()
+	pipe := rdb.TxPipeline()
+	pipe.LPush(reqCtx, auditEventsKey, data)
+	pipe.LTrim(reqCtx, auditEventsKey, 0, auditMaxEvents-1)
+	if _, err := pipe.Exec(reqCtx); err != nil {
+		fmt.Printf("audit record failed: %v\n", err)
+	}
+}
+
+// recentAuditEvents 按时间倒序读取最近 n 条审计记录
+func recentAuditEvents(c *gin.Context, n int64) ([]AuditEvent, error) {
+	items, err := rdb.LRange(c.Request.Context(), auditEventsKey, 0, n-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]AuditEvent, 0, len(items))
+	for _, item := range items {
+		var event AuditEvent
+		if err := jsonAPI.Unmarshal([]byte(item), &event); err != nil {
+			fmt.Printf("audit decode failed: %v\n", err)
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
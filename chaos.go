@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// 故障注入（chaos）中间件：仅在 dev/staging 环境启用，
+// 按路由配置概率注入延迟、Redis错误、MySQL错误或直接断开连接，
+// 用于验证超时、重试、降级等容错逻辑是否真正生效
+
+var (
+	errChaosRedis = errors.New("chaos: injected redis error")
+	errChaosMySQL = errors.New("chaos: injected mysql error")
+)
+
+// ChaosRule 单个路由的故障注入规则，概率取值 [0, 1]
+type ChaosRule struct {
+	LatencyProb    float64 `json:"latency_prob"`
+	LatencyMs      int     `json:"latency_ms"`
+	RedisErrorProb float64 `json:"redis_error_prob"`
+	MySQLErrorProb float64 `json:"mysql_error_prob"`
+	DropProb       float64 `json:"drop_prob"`
+}
+
+// chaosRuleRequest 管理接口请求体，route 形如 "GET /api/v1/users/:id"
+type chaosRuleRequest struct {
+	Route string    `json:"route" binding:"required"`
+	Rule  ChaosRule `json:"rule"`
+}
+
+func (r ChaosRule) validate() error {
+	probs := map[string]float64{
+		"latency_prob":     r.LatencyProb,
+		"redis_error_prob": r.RedisErrorProb,
+		"mysql_error_prob": r.MySQLErrorProb,
+		"drop_prob":        r.DropProb,
+	}
+	for name, p := range probs {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("%s 必须在 [0, 1] 之间，实际值：%v", name, p)
+		}
+	}
+	if r.LatencyMs < 0 {
+		return fmt.Errorf("latency_ms 不能为负数，实际值：%d", r.LatencyMs)
+	}
+	return nil
+}
+
+// chaosFaults 本次请求命中的故障，通过 request context 传递给 Redis/MySQL 钩子
+type chaosFaults struct {
+	redis bool
+	mysql bool
+}
+
+type chaosFaultsKey struct{}
+
+var (
+	chaosMu    sync.RWMutex
+	chaosRules = map[string]ChaosRule{}
+
+	// chaosTargets 允许注入故障的路由（已注册且不属于 /admin），启动时由 setChaosTargets 写入
+	chaosTargets = map[string]bool{}
+)
+
+// setChaosTargets 记录可注入故障的路由，需在所有路由注册完成后调用
+func setChaosTargets(routes gin.RoutesInfo) {
+	targets := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		if isAdminPath(route.Path) {
+			continue
+		}
+		targets[chaosRouteKey(route.Method, route.Path)] = true
+	}
+
+	chaosMu.Lock()
+	chaosTargets = targets
+	chaosMu.Unlock()
+}
+
+// isAdminPath 管理接口/后台不允许注入故障，避免无法再通过管理接口撤销规则
+func isAdminPath(path string) bool {
+	return path == "/admin" || strings.HasPrefix(path, "/admin/")
+}
+
+// chaosEnabled 仅 APP_ENV 为 dev 或 staging 时启用故障注入
+func chaosEnabled() bool {
+	switch os.Getenv("APP_ENV") {
+	case "dev", "staging":
+		return true
+	}
+	return false
+}
+
+func chaosRouteKey(method, path string) string {
+	return method + " " + path
+}
+
+func lookupChaosRule(key string) (ChaosRule, bool) {
+	chaosMu.RLock()
+	defer chaosMu.RUnlock()
+	rule, ok := chaosRules[key]
+	return rule, ok
+}
+
+func hit(prob float64) bool {
+	return prob > 0 && rand.Float64() < prob
+}
+
+// chaosMiddleware 按当前路由的规则注入故障
+func chaosMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rule, ok := lookupChaosRule(chaosRouteKey(c.Request.Method, c.FullPath()))
+		if !ok {
+			c.Next()
+			return
+		}
+
+		// 1. 丢弃响应：直接断开TCP连接，模拟网络中断
+		if hit(rule.DropProb) {
+			if conn, _, err := c.Writer.Hijack(); err == nil {
+				conn.Close()
+				c.Abort()
+				return
+			}
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+
+		var injected []string
+
+		// 2. 注入延迟（客户端断开时提前结束）
+		if rule.LatencyMs > 0 && hit(rule.LatencyProb) {
+			timer := time.NewTimer(time.Duration(rule.LatencyMs) * time.Millisecond)
+			select {
+			case <-timer.C:
+			case <-c.Request.Context().Done():
+				timer.Stop()
+			}
+			injected = append(injected, "latency")
+		}
+
+		// 3. 标记本次请求的 Redis/MySQL 故障，由钩子在实际调用时返回错误
+		faults := chaosFaults{
+			redis: hit(rule.RedisErrorProb),
+			mysql: hit(rule.MySQLErrorProb),
+		}
+		if faults.redis {
+			injected = append(injected, "redis")
+		}
+		if faults.mysql {
+			injected = append(injected, "mysql")
+		}
+		if faults.redis || faults.mysql {
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), chaosFaultsKey{}, faults))
+		}
+
+		if len(injected) > 0 {
+			c.Header("X-Chaos-Injected", strings.Join(injected, ","))
+		}
+
+		c.Next()
+	}
+}
+
+func faultsFromContext(ctx context.Context) chaosFaults {
+	if ctx == nil {
+		return chaosFaults{}
+	}
+	faults, _ := ctx.Value(chaosFaultsKey{}).(chaosFaults)
+	return faults
+}
+
+// chaosRedisHook 命中 Redis 故障的请求，所有命令直接返回错误
+type chaosRedisHook struct{}
+
+func (chaosRedisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	if faultsFromContext(ctx).redis {
+		return ctx, errChaosRedis
+	}
+	return ctx, nil
+}
+
+func (chaosRedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	return nil
+}
+
+func (chaosRedisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	if faultsFromContext(ctx).redis {
+		return ctx, errChaosRedis
+	}
+	return ctx, nil
+}
+
+func (chaosRedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	return nil
+}
+
+// chaosMySQLCallback 命中 MySQL 故障的请求，在真正执行SQL前写入错误
+func chaosMySQLCallback(tx *gorm.DB) {
+	if faultsFromContext(tx.Statement.Context).mysql {
+		tx.AddError(errChaosMySQL)
+	}
+}
+
+// initChaos 为 Redis 和 MySQL 挂载故障注入钩子
+func initChaos() error {
+	rdb.AddHook(chaosRedisHook{})
+
+	cb := db.Callback()
+	hooks := []struct {
+		name     string
+		register func() error
+	}{
+		{"chaos:create", func() error { return cb.Create().Before("gorm:create").Register("chaos:create", chaosMySQLCallback) }},
+		{"chaos:query", func() error { return cb.Query().Before("gorm:query").Register("chaos:query", chaosMySQLCallback) }},
+		{"chaos:update", func() error { return cb.Update().Before("gorm:update").Register("chaos:update", chaosMySQLCallback) }},
+		{"chaos:delete", func() error { return cb.Delete().Before("gorm:delete").Register("chaos:delete", chaosMySQLCallback) }},
+		{"chaos:row", func() error { return cb.Row().Before("gorm:row").Register("chaos:row", chaosMySQLCallback) }},
+		{"chaos:raw", func() error { return cb.Raw().Before("gorm:raw").Register("chaos:raw", chaosMySQLCallback) }},
+	}
+	for _, h := range hooks {
+		if err := h.register(); err != nil {
+			return fmt.Errorf("register %s failed: %v", h.name, err)
+		}
+	}
+	return nil
+}
+
+// listChaosRules 查看当前所有故障注入规则
+func listChaosRules(c *gin.Context) {
+	chaosMu.RLock()
+	rules := make(map[string]ChaosRule, len(chaosRules))
+	for k, v := range chaosRules {
+		rules[k] = v
+	}
+	chaosMu.RUnlock()
+
+	renderJSON(c, http.StatusOK, gin.H{"data": rules, "count": len(rules)})
+}
+
+// setChaosRule 新增或覆盖某个路由的故障注入规则
+func setChaosRule(c *gin.Context) {
+	var req chaosRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		renderJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.Rule.validate(); err != nil {
+		renderJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	chaosMu.Lock()
+	allowed := chaosTargets[req.Route]
+	if allowed {
+		chaosRules[req.Route] = req.Rule
+	}
+	chaosMu.Unlock()
+
+	if !allowed {
+		renderJSON(c, http.StatusBadRequest, gin.H{"error": fmt.Sprintf("route 必须是已注册的非管理路由，形如 \"GET /api/v1/users/:id\"，实际值：%s", req.Route)})
+		return
+	}
+
+	renderJSON(c, http.StatusOK, gin.H{"message": "chaos rule set", "data": req})
+}
+
+// deleteChaosRule 删除某个路由的规则，未指定 route 时清空全部规则
+func deleteChaosRule(c *gin.Context) {
+	route := c.Query("route")
+
+	chaosMu.Lock()
+	if route == "" {
+		chaosRules = map[string]ChaosRule{}
+	} else {
+		delete(chaosRules, route)
+	}
+	chaosMu.Unlock()
+
+	renderJSON(c, http.StatusOK, gin.H{"message": "chaos rule deleted"})
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// useChaosState 清空故障注入规则和可注入路由，测试结束后恢复
+func useChaosState(t *testing.T) {
+	t.Helper()
+
+	chaosMu.Lock()
+	prevRules, prevTargets := chaosRules, chaosTargets
+	chaosRules, chaosTargets = map[string]ChaosRule{}, map[string]bool{}
+	chaosMu.Unlock()
+
+	t.Cleanup(func() {
+		chaosMu.Lock()
+		chaosRules, chaosTargets = prevRules, prevTargets
+		chaosMu.Unlock()
+	})
+}
+
+func TestChaosRuleValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		rule    ChaosRule
+		wantErr bool
+	}{
+		{"zero", ChaosRule{}, false},
+		{"bounds", ChaosRule{LatencyProb: 1, LatencyMs: 100, RedisErrorProb: 0, MySQLErrorProb: 1, DropProb: 0.5}, false},
+		{"latency prob above 1", ChaosRule{LatencyProb: 1.1}, true},
+		{"redis prob negative", ChaosRule{RedisErrorProb: -0.1}, true},
+		{"mysql prob above 1", ChaosRule{MySQLErrorProb: 2}, true},
+		{"drop prob negative", ChaosRule{DropProb: -1}, true},
+		{"negative latency", ChaosRule{LatencyMs: -1}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.rule.validate(); (err != nil) != tc.wantErr {
+				t.Fatalf("validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetChaosRule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useChaosState(t)
+
+	r := gin.New()
+	r.GET("/api/v1/users/:id", func(c *gin.Context) {})
+	r.PUT("/admin/api/chaos", setChaosRule)
+	setChaosTargets(r.Routes())
+
+	cases := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"registered route", `{"route":"GET /api/v1/users/:id","rule":{"latency_prob":0.5,"latency_ms":10}}`, http.StatusOK},
+		{"admin route", `{"route":"PUT /admin/api/chaos","rule":{"drop_prob":1}}`, http.StatusBadRequest},
+		{"unknown route", `{"route":"GET /api/v1/orders","rule":{"drop_prob":1}}`, http.StatusBadRequest},
+		{"wrong method", `{"route":"DELETE /api/v1/users/:id","rule":{"drop_prob":1}}`, http.StatusBadRequest},
+		{"invalid probability", `{"route":"GET /api/v1/users/:id","rule":{"drop_prob":1.5}}`, http.StatusBadRequest},
+		{"missing route", `{"rule":{"drop_prob":1}}`, http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/admin/api/chaos", strings.NewReader(tc.body))
+			if w := serve(r, req); w.Code != tc.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tc.want, w.Body.String())
+			}
+		})
+	}
+
+	chaosMu.RLock()
+	defer chaosMu.RUnlock()
+	if len(chaosRules) != 1 {
+		t.Fatalf("rules = %v, want only the registered route", chaosRules)
+	}
+	if rule := chaosRules["GET /api/v1/users/:id"]; rule.LatencyMs != 10 {
+		t.Errorf("stored rule = %+v", rule)
+	}
+}
+
+func TestChaosMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useChaosState(t)
+
+	var got chaosFaults
+	r := gin.New()
+	r.Use(chaosMiddleware())
+	r.GET("/api/v1/users/:id", func(c *gin.Context) {
+		got = faultsFromContext(c.Request.Context())
+	})
+
+	cases := []struct {
+		name       string
+		rule       *ChaosRule
+		header     string
+		wantFaults chaosFaults
+	}{
+		{"no rule", nil, "", chaosFaults{}},
+		{"zero probabilities", &ChaosRule{LatencyMs: 10}, "", chaosFaults{}},
+		{"latency", &ChaosRule{LatencyProb: 1, LatencyMs: 1}, "latency", chaosFaults{}},
+		{"redis", &ChaosRule{RedisErrorProb: 1}, "redis", chaosFaults{redis: true}},
+		{"all", &ChaosRule{LatencyProb: 1, LatencyMs: 1, RedisErrorProb: 1, MySQLErrorProb: 1}, "latency,redis,mysql", chaosFaults{redis: true, mysql: true}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			chaosMu.Lock()
+			chaosRules = map[string]ChaosRule{}
+			if tc.rule != nil {
+				chaosRules["GET /api/v1/users/:id"] = *tc.rule
+			}
+			chaosMu.Unlock()
+			got = chaosFaults{}
+
+			w := serve(r, httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", w.Code)
+			}
+			if h := w.Header().Get("X-Chaos-Injected"); h != tc.header {
+				t.Errorf("X-Chaos-Injected = %q, want %q", h, tc.header)
+			}
+			if got != tc.wantFaults {
+				t.Errorf("faults = %+v, want %+v", got, tc.wantFaults)
+			}
+		})
+	}
+}
+
+func TestChaosRedisHook(t *testing.T) {
+	useTestRedis(t)
+	rdb.AddHook(chaosRedisHook{})
+
+	if err := rdb.Set(context.Background(), "user:1", "{}", 0).Err(); err != nil {
+		t.Fatalf("set without faults: %v", err)
+	}
+
+	faulty := context.WithValue(context.Background(), chaosFaultsKey{}, chaosFaults{redis: true})
+	if err := rdb.Get(faulty, "user:1").Err(); !errors.Is(err, errChaosRedis) {
+		t.Errorf("get with redis fault: err = %v, want %v", err, errChaosRedis)
+	}
+
+	pipe := rdb.TxPipeline()
+	pipe.Get(faulty, "user:1")
+	if _, err := pipe.Exec(faulty); !errors.Is(err, errChaosRedis) {
+		t.Errorf("pipeline with redis fault: err = %v, want %v", err, errChaosRedis)
+	}
+
+	mysqlOnly := context.WithValue(context.Background(), chaosFaultsKey{}, chaosFaults{mysql: true})
+	if err := rdb.Get(mysqlOnly, "user:1").Err(); err != nil {
+		t.Errorf("get with mysql-only fault: err = %v, want nil", err)
+	}
+}
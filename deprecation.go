@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 接口废弃标记：为即将下线的接口输出 Deprecation/Sunset/Link 响应头（RFC 9745 / RFC 8594），
+// 在响应信封中加入 warning 字段，并在Redis中统计调用次数，便于判断何时可以安全删除
+
+const (
+	deprecationWarningKey = "deprecation_warning"
+
+	deprecationUsageKey    = "deprecation:usage"
+	deprecationLastSeenKey = "deprecation:last_seen"
+)
+
+// Deprecation 单个废弃接口的元数据
+type Deprecation struct {
+	Name      string    `json:"name"`      // 唯一标识，用作调用计数的key
+	Since     time.Time `json:"since"`     // 开始废弃的时间
+	Sunset    time.Time `json:"sunset"`    // 计划下线时间，零值表示未定
+	Doc       string    `json:"doc"`       // 迁移说明文档
+	Successor string    `json:"successor"` // 替代接口
+	Message   string    `json:"message"`   // 写入响应 warning 字段的提示
+
+	// Match 仅部分请求形态被废弃时使用，nil 表示整个路由都已废弃
+	Match func(c *gin.Context) bool `json:"-"`
+}
+
+var (
+	deprecationsMu sync.RWMutex
+	deprecations   []Deprecation
+)
+
+// deprecated 返回标记接口废弃的中间件，注册路由时放在handler之前
+func deprecated(d Deprecation) gin.HandlerFunc {
+	deprecationsMu.Lock()
+	deprecations = append(deprecations, d)
+	deprecationsMu.Unlock()
+
+	return func(c *gin.Context) {
+		if d.Match != nil && !d.Match(c) {
+			c.Next()
+			return
+		}
+
+		c.Header("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+		if !d.Sunset.IsZero() {
+			c.Header("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if d.Doc != "" {
+			c.Writer.Header().Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"`, d.Doc))
+		}
+		if d.Successor != "" {
+			c.Writer.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, d.Successor))
+		}
+		if d.Message != "" {
+			c.Set(deprecationWarningKey, d.Message)
+		}
+
+		recordDeprecatedUsage(c, d.Name)
+		c.Next()
+	}
+}
+
+// recordDeprecatedUsage 累加调用次数并记录最近调用时间（仅打印日志，不影响接口返回）
+func recordDeprecatedUsage(c *gin.Context, name string) {
+	reqCtx := c.Request.Context()
+	pipe := rdb.TxPipeline()
+	pipe.HIncrBy(reqCtx, deprecationUsageKey, name, 1)
+	pipe.HSet(reqCtx, deprecationLastSeenKey, name, time.Now().Unix())
+	if _, err := pipe.Exec(reqCtx); err != nil {
+		fmt.Printf("record deprecated usage failed: %v\n", err)
+	}
+}
+
+// deprecationWarning 当前请求命中的废弃提示，未命中时为空
+func deprecationWarning(c *gin.Context) string {
+	return c.GetString(deprecationWarningKey)
+}
+
+// listDeprecations 查看所有废弃接口及其调用统计
+func listDeprecations(c *gin.Context) {
+	reqCtx := c.Request.Context()
+	usage, err := rdb.HGetAll(reqCtx, deprecationUsageKey).Result()
+	if err != nil {
+		renderJSON(c, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	lastSeen, err := rdb.HGetAll(reqCtx, deprecationLastSeenKey).Result()
+	if err != nil {
+		renderJSON(c, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	deprecationsMu.RLock()
+	defer deprecationsMu.RUnlock()
+
+	items := make([]gin.H, 0, len(deprecations))
+	for _, d := range deprecations {
+		count, _ := strconv.ParseInt(usage[d.Name], 10, 64)
+		item := gin.H{"deprecation": d, "usage": count, "last_seen": nil}
+		if ts, err := strconv.ParseInt(lastSeen[d.Name], 10, 64); err == nil {
+			item["last_seen"] = time.Unix(ts, 0)
+		}
+		items = append(items, item)
+	}
+
+	renderJSON(c, http.StatusOK, gin.H{"data": items, "count": len(items)})
+}
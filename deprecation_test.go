@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newDeprecationRouter 按 main 中的方式注册废弃的不分页列表，测试结束后恢复废弃接口登记表
+func newDeprecationRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	deprecationsMu.Lock()
+	prev := deprecations
+	deprecations = nil
+	deprecationsMu.Unlock()
+	t.Cleanup(func() {
+		deprecationsMu.Lock()
+		deprecations = prev
+		deprecationsMu.Unlock()
+	})
+
+	r := gin.New()
+	r.GET("/api/v1/users", deprecated(unpagedListDeprecation), listUsers)
+	return r
+}
+
+func TestDeprecatedUnpagedList(t *testing.T) {
+	useTestDB(t, 2)
+	mr := useTestRedis(t)
+	r := newDeprecationRouter(t)
+	d := unpagedListDeprecation
+
+	for i := 1; i <= 2; i++ {
+		w := serve(r, httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+
+		if got, want := w.Header().Get("Deprecation"), "@"+strconv.FormatInt(d.Since.Unix(), 10); got != want {
+			t.Errorf("Deprecation = %q, want %q", got, want)
+		}
+		if got, want := w.Header().Get("Sunset"), d.Sunset.UTC().Format(http.TimeFormat); got != want {
+			t.Errorf("Sunset = %q, want %q", got, want)
+		}
+		if _, err := http.ParseTime(w.Header().Get("Sunset")); err != nil {
+			t.Errorf("Sunset is not an HTTP-date: %v", err)
+		}
+		successor := `<` + d.Successor + `>; rel="successor-version"`
+		if links := w.Header().Values("Link"); !slices.Contains(links, successor) {
+			t.Errorf("Link = %q, want %s", links, successor)
+		}
+
+		var body struct {
+			Warning string `json:"warning"`
+			Count   int    `json:"count"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v: %s", err, w.Body.String())
+		}
+		if body.Warning != d.Message || body.Count != 2 {
+			t.Errorf("warning = %q, count = %d", body.Warning, body.Count)
+		}
+
+		if got := mr.HGet(deprecationUsageKey, d.Name); got != strconv.Itoa(i) {
+			t.Errorf("usage after %d calls = %q", i, got)
+		}
+		if mr.HGet(deprecationLastSeenKey, d.Name) == "" {
+			t.Error("last_seen not recorded")
+		}
+	}
+}
+
+func TestDeprecatedPagedListUnaffected(t *testing.T) {
+	useTestDB(t, 2)
+	mr := useTestRedis(t)
+	r := newDeprecationRouter(t)
+
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/api/v1/users?page=1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	for _, h := range []string{"Deprecation", "Sunset"} {
+		if v := w.Header().Get(h); v != "" {
+			t.Errorf("%s = %q, want absent", h, v)
+		}
+	}
+	// 分页请求仍有翻页用的 Link，但不应包含 successor-version
+	for _, link := range w.Header().Values("Link") {
+		if strings.Contains(link, "successor-version") {
+			t.Errorf("unexpected successor link: %s", link)
+		}
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v: %s", err, w.Body.String())
+	}
+	if _, ok := body["warning"]; ok {
+		t.Errorf("unexpected warning: %v", body["warning"])
+	}
+
+	if mr.Exists(deprecationUsageKey) {
+		t.Error("usage recorded for paged request")
+	}
+}
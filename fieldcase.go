@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 响应字段命名风格，由环境变量 JSON_FIELD_CASE 控制：
+//   - snake（默认）：与结构体 json tag 一致，原样输出
+//   - camel：渲染层转换为 camelCase，如 created_at -> createdAt
+//
+// 转换按类型进行：每个类型首次输出时生成一个 json tag 为 camelCase 的镜像类型并缓存，
+// 之后只需把字段值拷贝到镜像类型再交给 jsonAPI 编码，不会对序列化结果再解码改写。
+// 只转换结构体字段名和 gin.H 信封的key，其余 map 的key（路由、废弃接口名等）保持原样
+
+const (
+	fieldCaseSnake = "snake"
+	fieldCaseCamel = "camel"
+)
+
+var (
+	fieldCase = fieldCaseSnake
+
+	// camelPlans 各类型的转换方式（reflect.Type -> *camelPlan），nil 表示无需转换
+	camelPlans sync.Map
+
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	ginHType          = reflect.TypeOf(gin.H{})
+)
+
+// initFieldCase 读取响应字段命名风格配置
+func initFieldCase() error {
+	switch v := os.Getenv("JSON_FIELD_CASE"); v {
+	case "":
+		fieldCase = fieldCaseSnake
+	case fieldCaseSnake, fieldCaseCamel:
+		fieldCase = v
+	default:
+		return fmt.Errorf("unknown JSON_FIELD_CASE: %s (可选 snake / camel)", v)
+	}
+	return nil
+}
+
+// applyFieldCase 按配置转换待输出的值，snake 或无需转换的类型原样返回
+func applyFieldCase(v any) any {
+	if fieldCase != fieldCaseCamel || v == nil {
+		return v
+	}
+
+	rv := reflect.ValueOf(v)
+	plan := camelPlanFor(rv.Type())
+	if plan == nil {
+		return v
+	}
+	return plan.convert(rv).Interface()
+}
+
+// camelName snake_case 转 camelCase，不含下划线的名称原样返回
+func camelName(name string) string {
+	if !strings.Contains(name, "_") {
+		return name
+	}
+
+	parts := strings.Split(name, "_")
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	return b.String()
+}
+
+// camelPlan 某个类型转换为 camelCase 输出的方式
+type camelPlan struct {
+	kind   reflect.Kind
+	mirror reflect.Type // 转换后的类型
+
+	fields []int        // struct：镜像类型各字段对应的源字段下标
+	subs   []*camelPlan // struct：各字段值的转换方式
+
+	elem       *camelPlan // ptr/slice/array/map：元素的转换方式
+	renameKeys bool       // map：gin.H 信封的key同样转换
+}
+
+func camelPlanFor(t reflect.Type) *camelPlan {
+	if plan, ok := camelPlans.Load(t); ok {
+		return plan.(*camelPlan)
+	}
+
+	plan := buildCamelPlan(t, map[reflect.Type]bool{})
+	camelPlans.Store(t, plan)
+	return plan
+}
+
+// buildCamelPlan 生成类型的转换方式；自定义序列化的类型（time.Time、CustomTime 等）、
+// 含匿名嵌入字段的结构体和递归类型的内层原样输出
+func buildCamelPlan(t reflect.Type, visiting map[reflect.Type]bool) *camelPlan {
+	if customMarshaler(t) || visiting[t] {
+		return nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Interface:
+		// 仅 any 在运行时按实际类型转换，非空接口替换为镜像类型后不再满足接口
+		if t.NumMethod() > 0 {
+			return nil
+		}
+		return &camelPlan{kind: reflect.Interface, mirror: t}
+
+	case reflect.Pointer:
+		if elem := buildCamelPlan(t.Elem(), visiting); elem != nil {
+			return &camelPlan{kind: reflect.Pointer, mirror: reflect.PointerTo(elem.mirror), elem: elem}
+		}
+
+	case reflect.Slice:
+		if elem := buildCamelPlan(t.Elem(), visiting); elem != nil {
+			return &camelPlan{kind: reflect.Slice, mirror: reflect.SliceOf(elem.mirror), elem: elem}
+		}
+
+	case reflect.Array:
+		if elem := buildCamelPlan(t.Elem(), visiting); elem != nil {
+			return &camelPlan{kind: reflect.Array, mirror: reflect.ArrayOf(t.Len(), elem.mirror), elem: elem}
+		}
+
+	case reflect.Map:
+		elem := buildCamelPlan(t.Elem(), visiting)
+		renameKeys := t == ginHType
+		if elem == nil && !renameKeys {
+			return nil
+		}
+		mirror := t
+		if elem != nil {
+			mirror = reflect.MapOf(t.Key(), elem.mirror)
+		}
+		return &camelPlan{kind: reflect.Map, mirror: mirror, elem: elem, renameKeys: renameKeys}
+
+	case reflect.Struct:
+		return buildCamelStructPlan(t, visiting)
+	}
+	return nil
+}
+
+func buildCamelStructPlan(t reflect.Type, visiting map[reflect.Type]bool) *camelPlan {
+	plan := &camelPlan{kind: reflect.Struct}
+	var fields []reflect.StructField
+	changed := false
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			return nil
+		}
+		tag := f.Tag.Get("json")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		if camel := camelName(name); camel != name {
+			name, changed = camel, true
+		}
+		if opts != "" {
+			name += "," + opts
+		}
+
+		typ := f.Type
+		sub := buildCamelPlan(f.Type, visiting)
+		if sub != nil {
+			typ, changed = sub.mirror, true
+		}
+
+		fields = append(fields, reflect.StructField{
+			Name: f.Name,
+			Type: typ,
+			Tag:  reflect.StructTag(fmt.Sprintf("json:%q", name)),
+		})
+		plan.fields = append(plan.fields, i)
+		plan.subs = append(plan.subs, sub)
+	}
+
+	if !changed {
+		return nil
+	}
+	plan.mirror = reflect.StructOf(fields)
+	return plan
+}
+
+func customMarshaler(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		ptr.Implements(jsonMarshalerType) || ptr.Implements(textMarshalerType)
+}
+
+// convert 把值拷贝为镜像类型，plan 为 nil 时原样返回
+func (p *camelPlan) convert(v reflect.Value) reflect.Value {
+	if p == nil {
+		return v
+	}
+
+	switch p.kind {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		inner := v.Elem()
+		return camelPlanFor(inner.Type()).convert(inner)
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return reflect.Zero(p.mirror)
+		}
+		out := reflect.New(p.elem.mirror)
+		out.Elem().Set(p.elem.convert(v.Elem()))
+		return out
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(p.mirror)
+		}
+		out := reflect.MakeSlice(p.mirror, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(p.elem.convert(v.Index(i)))
+		}
+		return out
+
+	case reflect.Array:
+		out := reflect.New(p.mirror).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(p.elem.convert(v.Index(i)))
+		}
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(p.mirror)
+		}
+		out := reflect.MakeMapWithSize(p.mirror, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key()
+			if p.renameKeys {
+				key = reflect.ValueOf(camelName(key.String()))
+			}
+			out.SetMapIndex(key, p.elem.convert(iter.Value()))
+		}
+		return out
+
+	case reflect.Struct:
+		out := reflect.New(p.mirror).Elem()
+		for i, src := range p.fields {
+			out.Field(i).Set(p.subs[i].convert(v.Field(src)))
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func useFieldCase(t *testing.T, fc string) {
+	t.Helper()
+	prev := fieldCase
+	fieldCase = fc
+	t.Cleanup(func() { fieldCase = prev })
+}
+
+func TestCamelName(t *testing.T) {
+	cases := map[string]string{
+		"id":               "id",
+		"created_at":       "createdAt",
+		"redis_error_prob": "redisErrorProb",
+		"page_size":        "pageSize",
+		"CreateAt":         "CreateAt",
+		"trailing_":        "trailing",
+	}
+	for in, want := range cases {
+		if got := camelName(in); got != want {
+			t.Errorf("camelName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestInitFieldCase(t *testing.T) {
+	useFieldCase(t, fieldCaseSnake)
+
+	for _, v := range []string{"", "snake", "camel"} {
+		t.Setenv("JSON_FIELD_CASE", v)
+		if err := initFieldCase(); err != nil {
+			t.Errorf("JSON_FIELD_CASE=%q: %v", v, err)
+		}
+	}
+	t.Setenv("JSON_FIELD_CASE", "kebab")
+	if err := initFieldCase(); err == nil {
+		t.Error("JSON_FIELD_CASE=kebab should be rejected")
+	}
+}
+
+func TestRenderValueFieldCase(t *testing.T) {
+	user := User{ID: 1, Name: "gin", Email: "gin@example.com", CreateAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+	rule := ChaosRule{LatencyProb: 0.5}
+	obj := gin.H{
+		"data":      []User{user},
+		"page_size": 20,
+		"owner":     &user,
+		"missing":   (*User)(nil),
+		// 非 gin.H 的 map 的key为业务数据，只转换value
+		"rules": map[string]ChaosRule{"GET /api/v1/users/:id": rule},
+		"items": []gin.H{{"last_seen": nil}},
+	}
+
+	t.Run("snake", func(t *testing.T) {
+		useFieldCase(t, fieldCaseSnake)
+		if got := applyFieldCase(obj); !reflect.DeepEqual(got, obj) {
+			t.Errorf("snake should return the value unchanged, got %#v", got)
+		}
+	})
+
+	t.Run("camel", func(t *testing.T) {
+		useFieldCase(t, fieldCaseCamel)
+
+		data, err := renderValue(obj, false, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]any{
+			"data": []any{map[string]any{
+				"id": 1.0, "name": "gin", "email": "gin@example.com",
+				"createdAt": "2025-01-02T03:04:05Z", "updatedAt": "0001-01-01T00:00:00Z",
+			}},
+			"pageSize": 20.0,
+			"owner": map[string]any{
+				"id": 1.0, "name": "gin", "email": "gin@example.com",
+				"createdAt": "2025-01-02T03:04:05Z", "updatedAt": "0001-01-01T00:00:00Z",
+			},
+			"missing": nil,
+			"rules": map[string]any{"GET /api/v1/users/:id": map[string]any{
+				"latencyProb": 0.5, "latencyMs": 0.0, "redisErrorProb": 0.0, "mysqlErrorProb": 0.0, "dropProb": 0.0,
+			}},
+			"items": []any{map[string]any{"lastSeen": nil}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("camel output:\n got  %s\n want %v", data, want)
+		}
+	})
+}
+
+// 镜像类型按类型缓存，同一类型只生成一次
+func TestCamelPlanCached(t *testing.T) {
+	typ := reflect.TypeOf(User{})
+	first := camelPlanFor(typ)
+	if first == nil {
+		t.Fatal("User should need conversion")
+	}
+	if again := camelPlanFor(typ); again != first {
+		t.Error("plan for User was rebuilt")
+	}
+	if plan := camelPlanFor(reflect.TypeOf(time.Time{})); plan != nil {
+		t.Error("time.Time has its own MarshalJSON and should be left unchanged")
+	}
+}
+
+func TestListUsersCamelCase(t *testing.T) {
+	useFieldCase(t, fieldCaseCamel)
+	useTestDB(t, 2)
+	r := newListRouter("")
+
+	for _, target := range []string{"/api/v1/users", "/api/v1/users/export"} {
+		w := serve(r, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s status = %d", target, w.Code)
+		}
+		body := w.Body.String()
+		for _, key := range []string{`"createdAt"`, `"updatedAt"`} {
+			if !strings.Contains(body, key) {
+				t.Errorf("%s missing %s: %s", target, key, body)
+			}
+		}
+		if strings.Contains(body, `"created_at"`) {
+			t.Errorf("%s still has snake_case keys: %s", target, body)
+		}
+	}
+}
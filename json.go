@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	ginjson "github.com/gin-gonic/gin/codec/json"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// jsonAPI 当前使用的JSON序列化实现，HTTP响应、请求绑定和Redis缓存共用
+// 通过环境变量 JSON_CODEC 切换：std（默认）、jsoniter、sonic（需 -tags sonic 编译）
+var jsonAPI ginjson.Core = stdJSON{}
+
+// jsonCodecs 已编译进二进制的JSON实现
+var jsonCodecs = map[string]ginjson.Core{
+	"std":      stdJSON{},
+	"jsoniter": jsoniterJSON{},
+}
+
+// initJSON 根据配置选择JSON实现，并同步替换gin内部使用的实现
+func initJSON() error {
+	name := os.Getenv("JSON_CODEC")
+	if name == "" {
+		name = "std"
+	}
+
+	codec, ok := jsonCodecs[name]
+	if !ok {
+		return fmt.Errorf("unknown JSON_CODEC: %s (sonic 需使用 -tags sonic 编译)", name)
+	}
+
+	jsonAPI = codec
+	ginjson.API = codec
+	return nil
+}
+
+// stdJSON 标准库 encoding/json
+type stdJSON struct{}
+
+func (stdJSON) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdJSON) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (stdJSON) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return json.MarshalIndent(v, prefix, indent)
+}
+
+func (stdJSON) NewEncoder(writer io.Writer) ginjson.Encoder {
+	return json.NewEncoder(writer)
+}
+
+func (stdJSON) NewDecoder(reader io.Reader) ginjson.Decoder {
+	return json.NewDecoder(reader)
+}
+
+// jsoniterJSON json-iterator/go，使用与标准库兼容的配置
+type jsoniterJSON struct{}
+
+var jsoniterStd = jsoniter.ConfigCompatibleWithStandardLibrary
+
+func (jsoniterJSON) Marshal(v any) ([]byte, error) {
+	return jsoniterStd.Marshal(v)
+}
+
+func (jsoniterJSON) Unmarshal(data []byte, v any) error {
+	return jsoniterStd.Unmarshal(data, v)
+}
+
+func (jsoniterJSON) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return jsoniterStd.MarshalIndent(v, prefix, indent)
+}
+
+func (jsoniterJSON) NewEncoder(writer io.Writer) ginjson.Encoder {
+	return jsoniterStd.NewEncoder(writer)
+}
+
+func (jsoniterJSON) NewDecoder(reader io.Reader) ginjson.Decoder {
+	return jsoniterStd.NewDecoder(reader)
+}
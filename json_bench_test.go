@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	ginjson "github.com/gin-gonic/gin/codec/json"
+)
+
+// getUser 缓存命中路径的JSON基准测试，对比各序列化实现：
+//
+//	go test -run '^$' -bench . -benchmem
+//	go test -run '^$' -bench . -benchmem -tags sonic
+
+var benchUser = User{
+	ID:       42,
+	Name:     "gin-learn",
+	Email:    "gin-learn@example.com",
+	CreateAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local),
+	UpdateAt: time.Date(2025, 6, 7, 8, 9, 10, 0, time.Local),
+}
+
+func useJSONCodec(b *testing.B, codec ginjson.Core) {
+	prevAPI, prevGin := jsonAPI, ginjson.API
+	jsonAPI, ginjson.API = codec, codec
+	b.Cleanup(func() {
+		jsonAPI, ginjson.API = prevAPI, prevGin
+	})
+}
+
+// BenchmarkCacheEncode 写入Redis前的用户序列化
+func BenchmarkCacheEncode(b *testing.B) {
+	for name, codec := range jsonCodecs {
+		b.Run(name, func(b *testing.B) {
+			useJSONCodec(b, codec)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := jsonAPI.Marshal(benchUser); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkGetUserCacheHit 缓存命中：调用 getUser，从 miniredis 读取缓存并渲染HTTP响应
+func BenchmarkGetUserCacheHit(b *testing.B) {
+	gin.SetMode(gin.ReleaseMode)
+
+	for name, codec := range jsonCodecs {
+		b.Run(name, func(b *testing.B) {
+			useJSONCodec(b, codec)
+
+			mr := useTestRedis(b)
+			cacheData, err := jsonAPI.Marshal(benchUser)
+			if err != nil {
+				b.Fatal(err)
+			}
+			mr.Set("user:42", string(cacheData))
+
+			r := gin.New()
+			r.GET("/api/v1/users/:id", getUser)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users/42", nil)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`"source":"redis"`)) {
+					b.Fatalf("cache miss or error %d: %s", w.Code, w.Body.String())
+				}
+			}
+		})
+	}
+}
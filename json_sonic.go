@@ -0,0 +1,38 @@
+//go:build sonic
+
+package main
+
+import (
+	"io"
+
+	"github.com/bytedance/sonic"
+	ginjson "github.com/gin-gonic/gin/codec/json"
+)
+
+// sonic 依赖JIT，仅在 -tags sonic 编译时注册（需 amd64/arm64 且 Go 版本受 sonic 支持）
+func init() {
+	jsonCodecs["sonic"] = sonicJSON{}
+}
+
+// sonicJSON bytedance/sonic，使用与标准库行为一致的 ConfigStd
+type sonicJSON struct{}
+
+func (sonicJSON) Marshal(v any) ([]byte, error) {
+	return sonic.ConfigStd.Marshal(v)
+}
+
+func (sonicJSON) Unmarshal(data []byte, v any) error {
+	return sonic.ConfigStd.Unmarshal(data, v)
+}
+
+func (sonicJSON) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return sonic.ConfigStd.MarshalIndent(v, prefix, indent)
+}
+
+func (sonicJSON) NewEncoder(writer io.Writer) ginjson.Encoder {
+	return sonic.ConfigStd.NewEncoder(writer)
+}
+
+func (sonicJSON) NewDecoder(reader io.Reader) ginjson.Decoder {
+	return sonic.ConfigStd.NewDecoder(reader)
+}
@@ -2,15 +2,17 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"io"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -29,7 +31,7 @@ const timeLayout = "2006-01-02 15:04:05"
 func (ct *CustomTime) UnmarshalJSON(data []byte) error {
 	// 去除字符串两端的引号
 	var timeStr string
-	if err := json.Unmarshal(data, &timeStr); err != nil {
+	if err := jsonAPI.Unmarshal(data, &timeStr); err != nil {
 		return err
 	}
 
@@ -44,6 +46,11 @@ func (ct *CustomTime) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalJSON 按 timeLayout 输出，与请求格式保持一致
+func (ct CustomTime) MarshalJSON() ([]byte, error) {
+	return jsonAPI.Marshal(ct.String())
+}
+
 // String 自定义输出格式（可选）
 func (ct CustomTime) String() string {
 	return time.Time(ct).Format(timeLayout)
@@ -60,8 +67,31 @@ type User struct {
 type UserRequest struct {
 	Name     string     `json:"name"`
 	Email    string     `json:"email"`
-	CreateAt CustomTime `json:"createAt"`
-	UpdateAt CustomTime `json:"updateAt"`
+	CreateAt CustomTime `json:"created_at"`
+	UpdateAt CustomTime `json:"updated_at"`
+}
+
+// UnmarshalJSON 字段名与 User 保持一致（created_at/updated_at），
+// 同时兼容旧字段名 createAt/updateAt，新字段优先
+func (r *UserRequest) UnmarshalJSON(data []byte) error {
+	type userRequest UserRequest
+	var req struct {
+		userRequest
+		LegacyCreateAt *CustomTime `json:"createAt"`
+		LegacyUpdateAt *CustomTime `json:"updateAt"`
+	}
+	if err := jsonAPI.Unmarshal(data, &req); err != nil {
+		return err
+	}
+
+	*r = UserRequest(req.userRequest)
+	if req.LegacyCreateAt != nil && time.Time(r.CreateAt).IsZero() {
+		r.CreateAt = *req.LegacyCreateAt
+	}
+	if req.LegacyUpdateAt != nil && time.Time(r.UpdateAt).IsZero() {
+		r.UpdateAt = *req.LegacyUpdateAt
+	}
+	return nil
 }
 
 func initMysql() error {
@@ -97,6 +127,18 @@ func main() {
 		panic(fmt.Sprintf("load .env failed: %v", err))
 	}
 
+	if err := initJSON(); err != nil {
+		panic(err)
+	}
+
+	if err := initFieldCase(); err != nil {
+		panic(err)
+	}
+
+	if err := initAssets(); err != nil {
+		panic(err)
+	}
+
 	if err := initMysql(); err != nil {
 		panic(err)
 	}
@@ -107,13 +149,54 @@ func main() {
 
 	r := gin.Default()
 
-	api := r.Group("/api/v1/users")
+	// 故障注入仅在 dev/staging 环境启用
+	if chaosEnabled() {
+		if err := initChaos(); err != nil {
+			panic(err)
+		}
+		r.Use(chaosMiddleware())
+	}
+
+	api := r.Group("/api/v1/users", signatureAuth())
 	{
-		api.POST("", createUser)       // 创建用户
-		api.GET("/:id", getUser)       // 查询用户
-		api.PUT("/:id", updateUser)    // 更新用户
-		api.DELETE("/:id", deleteUser) // 删除用户
-		api.GET("", listUsers)         // 获取用户列表（直接查MySQL）
+		api.POST("", createUser)                                   // 创建用户
+		api.GET("/:id", getUser)                                   // 查询用户
+		api.PUT("/:id", updateUser)                                // 更新用户
+		api.DELETE("/:id", deleteUser)                             // 删除用户
+		api.GET("", deprecated(unpagedListDeprecation), listUsers) // 获取用户列表（直接查MySQL）
+		api.GET("/export", exportUsers)                            // 导出全部用户（NDJSON）
+	}
+
+	admin := r.Group("/admin/api", adminAuth())
+	admin.GET("/deprecations", listDeprecations) // 查看废弃接口调用统计
+	if chaosEnabled() {
+		admin.GET("/chaos", listChaosRules)     // 查看故障注入规则
+		admin.PUT("/chaos", setChaosRule)       // 设置故障注入规则
+		admin.DELETE("/chaos", deleteChaosRule) // 删除故障注入规则
+	}
+
+	// 管理后台（服务端渲染）
+	if err := loadAdminTemplates(r); err != nil {
+		panic(err)
+	}
+	r.GET("/admin/static/*filepath", serveAdminStatic)
+	r.HEAD("/admin/static/*filepath", serveAdminStatic)
+	r.GET("/admin/login", adminLoginPage)
+	r.POST("/admin/login", adminLogin)
+	ui := r.Group("/admin", adminPageAuth())
+	{
+		ui.GET("", func(c *gin.Context) { c.Redirect(http.StatusFound, "/admin/users") })
+		ui.POST("/logout", adminLogout)          // 退出登录
+		ui.GET("/users", adminUsersPage)         // 用户搜索
+		ui.GET("/users/:id", adminUserPage)      // 用户详情
+		ui.POST("/users/:id", adminUpdateUser)   // 编辑用户
+		ui.GET("/cache", adminCachePage)         // 缓存状态
+		ui.POST("/cache/evict", adminEvictCache) // 删除缓存
+		ui.GET("/audit", adminAuditPage)         // 审计日志
+	}
+
+	if chaosEnabled() {
+		setChaosTargets(r.Routes())
 	}
 
 	// 启动服务
@@ -127,7 +210,7 @@ func createUser(c *gin.Context) {
 
 	// 绑定请求体
 	if err := c.ShouldBindBodyWithJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		renderJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -137,14 +220,17 @@ func createUser(c *gin.Context) {
 	user.CreateAt = time.Time(req.CreateAt)
 	user.UpdateAt = time.Time(req.UpdateAt)
 	// 写入MySQL
-	if err := db.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.WithContext(c.Request.Context()).Create(&user).Error; err != nil {
+		renderJSON(c, http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	recordAudit(c, "user.create", fmt.Sprintf("user:%d", user.ID), user.Email)
+
+	// 返回写入后的 User，时间字段格式与查询/列表接口一致
+	renderJSON(c, http.StatusCreated, gin.H{
 		"message": "user created",
-		"data":    req,
+		"data":    user,
 	})
 }
 
@@ -152,35 +238,33 @@ func createUser(c *gin.Context) {
 func getUser(c *gin.Context) {
 	id := c.Param("id")
 	cacheKey := fmt.Sprintf("user:%s", id)
+	reqCtx := c.Request.Context()
 
-	// 1. 先查Redis缓存
+	// 1. 先查Redis缓存（缓存完整用户JSON）
 	var user User
-	cacheData, err := rdb.Get(ctx, cacheKey).Result()
+	cacheData, err := rdb.Get(reqCtx, cacheKey).Bytes()
 	if err == nil {
-		// 缓存命中：直接返回（这里简化，实际可反序列化JSON）
-		// 注：示例中缓存仅存ID，实际项目可存完整JSON字符串
-		if err := db.Where("id = ?", id).First(&user).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		if err = jsonAPI.Unmarshal(cacheData, &user); err == nil {
+			renderJSON(c, http.StatusOK, gin.H{"data": user, "source": "redis"})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"data": user, "source": "redis"})
-		return
+		fmt.Printf("redis cache decode failed: %v\n", err) // 缓存损坏时回源MySQL
 	}
 
-	fmt.Println(cacheData)
-
 	// 2. 缓存未命中：查MySQL
-	if err := db.Where("id = ?", id).First(&user).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	if err := db.WithContext(reqCtx).Where("id = ?", id).First(&user).Error; err != nil {
+		renderJSON(c, http.StatusNotFound, gin.H{"error": "user not found"})
 		return
 	}
 
 	// 3. 写入Redis缓存
-	if err := rdb.Set(ctx, cacheKey, id, redisExpireTime).Err(); err != nil {
+	if data, err := jsonAPI.Marshal(user); err != nil {
+		fmt.Printf("redis cache encode failed: %v\n", err)
+	} else if err := rdb.Set(reqCtx, cacheKey, data, redisExpireTime).Err(); err != nil {
 		fmt.Printf("redis set failed: %v\n", err) // 仅打印日志，不影响接口返回
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": user, "source": "mysql"})
+	renderJSON(c, http.StatusOK, gin.H{"data": user, "source": "mysql"})
 }
 
 // updateUser 更新用户（更新MySQL，删除Redis缓存）
@@ -188,52 +272,207 @@ func updateUser(c *gin.Context) {
 	id := c.Param("id")
 	cacheKey := fmt.Sprintf("user:%s", id)
 
+	reqCtx := c.Request.Context()
+
 	var req User
 	if err := c.ShouldBindBodyWithJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		renderJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	// 更新MySQL
-	if err := db.Model(&User{}).Where("id = ?", id).Updates(req).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.WithContext(reqCtx).Model(&User{}).Where("id = ?", id).Updates(req).Error; err != nil {
+		renderJSON(c, http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// 删除Redis缓存（避免缓存脏数据）
-	if err := rdb.Del(ctx, cacheKey).Err(); err != nil {
+	if err := rdb.Del(reqCtx, cacheKey).Err(); err != nil {
 		fmt.Printf("redis del failed: %v\n", err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "user updated"})
+	recordAudit(c, "user.update", cacheKey, "")
+
+	renderJSON(c, http.StatusOK, gin.H{"message": "user updated"})
 }
 
 // deleteUser 删除用户（删除MySQL，删除Redis缓存）
 func deleteUser(c *gin.Context) {
 	id := c.Param("id")
 	cacheKey := fmt.Sprintf("user:%s", id)
+	reqCtx := c.Request.Context()
 
 	// 删除MySQL数据
-	if err := db.Where("id = ?", id).Delete(&User{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.WithContext(reqCtx).Where("id = ?", id).Delete(&User{}).Error; err != nil {
+		renderJSON(c, http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// 删除Redis缓存
-	if err := rdb.Del(ctx, cacheKey).Err(); err != nil {
+	if err := rdb.Del(reqCtx, cacheKey).Err(); err != nil {
 		fmt.Printf("redis del failed: %v\n", err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "user deleted"})
+	recordAudit(c, "user.delete", cacheKey, "")
+
+	renderJSON(c, http.StatusOK, gin.H{"message": "user deleted"})
+}
+
+// unpagedListDeprecation 不分页的用户列表在表数据量大时代价过高，计划下线
+var unpagedListDeprecation = Deprecation{
+	Name:      "GET /api/v1/users (unpaged)",
+	Since:     time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+	Sunset:    time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC),
+	Successor: "/api/v1/users?page=1&page_size=20",
+	Message:   "不分页的用户列表已废弃，将于 2027-01-31 下线，请改用 ?page=&page_size= 分页查询或 /api/v1/users/export 导出",
+	Match: func(c *gin.Context) bool {
+		_, paged, _ := parsePagination(c)
+		return !paged
+	},
 }
 
 // listUsers 获取用户列表（直接查MySQL，不缓存，避免列表频繁变化）
+// 携带 page/page_size 时分页返回；否则分批查询全部用户并逐条编码写入响应，内存占用不随表行数增长
 func listUsers(c *gin.Context) {
-	var users []User
-	if err := db.Find(&users).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	p, paged, err := parsePagination(c)
+	if err != nil {
+		renderJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if paged {
+		listUsersPage(c, p)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": users, "count": len(users)})
+	// 信封格式与 renderJSON 保持一致，pretty 时逐条缩进
+	pretty := wantPretty(c)
+	head, sep, rowPrefix := `{"data":[`, ",", ""
+	dataEnd, fieldBreak, keySep, closing := "]", "", ":", "}"
+	if pretty {
+		rowPrefix = prettyIndent + prettyIndent
+		head = "{\n" + prettyIndent + `"data": [` + "\n" + rowPrefix
+		sep = ",\n" + rowPrefix
+		dataEnd, fieldBreak, keySep, closing = "\n"+prettyIndent+"]", "\n"+prettyIndent, ": ", "\n}"
+	}
+
+	var warning []byte
+	if msg := deprecationWarning(c); msg != "" {
+		if warning, err = jsonAPI.Marshal(msg); err != nil {
+			renderJSON(c, http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	count := 0
+
+	err = streamUsers(c, func(u *User) error {
+		row, err := renderValue(u, pretty, rowPrefix)
+		if err != nil {
+			return err
+		}
+
+		delim := sep
+		if count == 0 {
+			// 首条数据到达时才写响应头，查询失败时仍可返回500
+			c.Header("Content-Type", "application/json; charset=utf-8")
+			c.Status(http.StatusOK)
+			delim = head
+		}
+		if _, err := io.WriteString(c.Writer, delim); err != nil {
+			return err
+		}
+		if _, err := c.Writer.Write(row); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		if !c.Writer.Written() {
+			renderJSON(c, http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		// 响应已部分写出，无法再修改状态码，保留截断的JSON由客户端识别
+		fmt.Printf("list users stream failed: %v\n", err)
+		return
+	}
+
+	if count == 0 {
+		renderJSON(c, http.StatusOK, gin.H{"data": []User{}, "count": 0})
+		return
+	}
+
+	// 结尾：],"count":N[,"warning":"..."]}
+	var tail strings.Builder
+	tail.WriteString(dataEnd)
+	tail.WriteString("," + fieldBreak + `"count"` + keySep + strconv.Itoa(count))
+	if warning != nil {
+		tail.WriteString("," + fieldBreak + `"warning"` + keySep)
+		tail.Write(warning)
+	}
+	tail.WriteString(closing)
+	if _, err := io.WriteString(c.Writer, tail.String()); err != nil {
+		fmt.Printf("list users stream failed: %v\n", err)
+	}
+}
+
+// listUsersPage 分页查询用户，通过 Link/X-Total-Count 响应头暴露翻页信息
+func listUsersPage(c *gin.Context, p pagination) {
+	reqCtx := c.Request.Context()
+	if err := db.WithContext(reqCtx).Model(&User{}).Count(&p.Total).Error; err != nil {
+		renderJSON(c, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	users := []User{}
+	if !p.pastEnd() {
+		if err := db.WithContext(reqCtx).Order("id").Offset(p.offset()).Limit(p.PageSize).Find(&users).Error; err != nil {
+			renderJSON(c, http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	setPaginationHeaders(c, p)
+	renderJSON(c, http.StatusOK, gin.H{
+		"data":      users,
+		"count":     len(users),
+		"page":      p.Page,
+		"page_size": p.PageSize,
+		"total":     p.Total,
+	})
+}
+
+// exportUsers 导出全部用户（NDJSON，每行一个用户，不受 pretty 影响）
+func exportUsers(c *gin.Context) {
+	written := false
+	writeHeader := func() {
+		c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="users.ndjson"`)
+		c.Status(http.StatusOK)
+	}
+
+	err := streamUsers(c, func(u *User) error {
+		row, err := renderValue(u, false, "")
+		if err != nil {
+			return err
+		}
+		if !written {
+			writeHeader()
+			written = true
+		}
+		_, err = c.Writer.Write(append(row, '\n'))
+		return err
+	})
+	if err != nil {
+		if !c.Writer.Written() {
+			renderJSON(c, http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		fmt.Printf("export users stream failed: %v\n", err)
+		return
+	}
+
+	if !written {
+		writeHeader()
+		c.Writer.WriteHeaderNow()
+	}
 }
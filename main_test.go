@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// useTestRedis 将全局 rdb 指向 miniredis，测试结束后恢复
+func useTestRedis(tb testing.TB) *miniredis.Miniredis {
+	tb.Helper()
+
+	mr := miniredis.RunT(tb)
+	prev := rdb
+	rdb = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	tb.Cleanup(func() {
+		rdb.Close()
+		rdb = prev
+	})
+	return mr
+}
+
+// useTestDB 将全局 db 指向内存 SQLite 并写入 n 个用户（ID 从1开始），测试结束后恢复
+func useTestDB(tb testing.TB, n int) {
+	tb.Helper()
+
+	conn, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		tb.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		tb.Fatalf("sqlite pool: %v", err)
+	}
+	// 每个连接都是独立的内存库，限制为单连接保证数据可见
+	sqlDB.SetMaxOpenConns(1)
+	if err := conn.AutoMigrate(&User{}); err != nil {
+		tb.Fatalf("migrate: %v", err)
+	}
+
+	if n > 0 {
+		created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		users := make([]User, n)
+		for i := range users {
+			users[i] = User{
+				Name:     fmt.Sprintf("user-%d", i+1),
+				Email:    fmt.Sprintf("user-%d@example.com", i+1),
+				CreateAt: created,
+				UpdateAt: created,
+			}
+		}
+		if err := conn.CreateInBatches(users, 200).Error; err != nil {
+			tb.Fatalf("seed users: %v", err)
+		}
+	}
+
+	prev := db
+	db = conn
+	tb.Cleanup(func() {
+		sqlDB.Close()
+		db = prev
+	})
+}
+
+func serve(r *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// 创建接口返回的用户与查询接口字段名、时间格式一致
+func TestCreateUserMatchesGetUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useTestDB(t, 0)
+	useTestRedis(t)
+
+	r := gin.New()
+	r.POST("/api/v1/users", createUser)
+	r.GET("/api/v1/users/:id", getUser)
+
+	body := `{"name":"gin","email":"gin@example.com","created_at":"2025-01-02 03:04:05","updated_at":"2025-01-02 03:04:05"}`
+	w := serve(r, httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+
+	w = serve(r, httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("get status = %d: %s", w.Code, w.Body.String())
+	}
+	var fetched struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &fetched); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"id", "name", "email", "created_at", "updated_at"} {
+		if created.Data[key] != fetched.Data[key] {
+			t.Errorf("%s: created %v, fetched %v", key, created.Data[key], fetched.Data[key])
+		}
+	}
+	if len(created.Data) != len(fetched.Data) {
+		t.Errorf("created fields %v, fetched fields %v", created.Data, fetched.Data)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+
+	// maxPage 限制页码上限，保证 offset 计算不会溢出
+	maxPage = 1000000
+)
+
+// pagination 分页参数：?page=1&page_size=20，page 从1开始
+type pagination struct {
+	Page     int
+	PageSize int
+	Total    int64
+}
+
+// parsePagination 解析分页参数，请求未携带 page/page_size 时 paged 为 false
+func parsePagination(c *gin.Context) (p pagination, paged bool, err error) {
+	pageStr, hasPage := c.GetQuery("page")
+	sizeStr, hasSize := c.GetQuery("page_size")
+	if !hasPage && !hasSize {
+		return p, false, nil
+	}
+
+	p.Page, p.PageSize = 1, defaultPageSize
+	if hasPage {
+		if p.Page, err = strconv.Atoi(pageStr); err != nil || p.Page < 1 || p.Page > maxPage {
+			return p, true, fmt.Errorf("page 必须在 1~%d 之间，实际值：%s", maxPage, pageStr)
+		}
+	}
+	if hasSize {
+		if p.PageSize, err = strconv.Atoi(sizeStr); err != nil || p.PageSize < 1 || p.PageSize > maxPageSize {
+			return p, true, fmt.Errorf("page_size 必须在 1~%d 之间，实际值：%s", maxPageSize, sizeStr)
+		}
+	}
+	return p, true, nil
+}
+
+func (p pagination) offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// lastPage 最后一页页码，无数据时为1
+func (p pagination) lastPage() int {
+	if p.Total <= 0 {
+		return 1
+	}
+	return int((p.Total + int64(p.PageSize) - 1) / int64(p.PageSize))
+}
+
+// pastEnd 页码超出最后一页，此时无需再查询数据
+func (p pagination) pastEnd() bool {
+	return p.Page > p.lastPage()
+}
+
+// setPaginationHeaders 写入 RFC 5988 Link 头（first/prev/next/last）和 X-Total-Count
+func setPaginationHeaders(c *gin.Context, p pagination) {
+	last := p.lastPage()
+
+	links := []string{pageLink(c, 1, p.PageSize, "first")}
+	if p.Page > 1 {
+		prev := p.Page - 1
+		if prev > last {
+			prev = last
+		}
+		links = append(links, pageLink(c, prev, p.PageSize, "prev"))
+	}
+	if p.Page < last {
+		links = append(links, pageLink(c, p.Page+1, p.PageSize, "next"))
+	}
+	links = append(links, pageLink(c, last, p.PageSize, "last"))
+
+	c.Writer.Header().Add("Link", strings.Join(links, ", "))
+	c.Header("X-Total-Count", strconv.FormatInt(p.Total, 10))
+}
+
+// pageLink 基于当前请求URL替换分页参数，保留其余查询参数
+func pageLink(c *gin.Context, page, pageSize int, rel string) string {
+	query := c.Request.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("page_size", strconv.Itoa(pageSize))
+
+	u := url.URL{
+		Scheme:   requestScheme(c),
+		Host:     c.Request.Host,
+		Path:     c.Request.URL.Path,
+		RawQuery: query.Encode(),
+	}
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.String(), rel)
+}
+
+// requestScheme 客户端访问使用的协议：优先取前置代理的 X-Forwarded-Proto，
+// 多级代理时取第一个值（最靠近客户端），只接受 http/https，其余情况按本进程是否终止TLS判断
+func requestScheme(c *gin.Context) string {
+	proto, _, _ := strings.Cut(c.GetHeader("X-Forwarded-Proto"), ",")
+	switch proto = strings.ToLower(strings.TrimSpace(proto)); proto {
+	case "http", "https":
+		return proto
+	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
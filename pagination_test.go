@@ -0,0 +1,122 @@
+package main
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newPaginationContext(target string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	return c, w
+}
+
+func TestSetPaginationHeaders(t *testing.T) {
+	const base = "http://example.com/api/v1/users?"
+	link := func(page int, rel string) string {
+		return `<` + base + `page=` + strconv.Itoa(page) + `&page_size=10>; rel="` + rel + `"`
+	}
+
+	cases := []struct {
+		name  string
+		page  int
+		total int64
+		links []string
+	}{
+		{"first", 1, 45, []string{link(1, "first"), link(2, "next"), link(5, "last")}},
+		{"middle", 3, 45, []string{link(1, "first"), link(2, "prev"), link(4, "next"), link(5, "last")}},
+		{"last", 5, 45, []string{link(1, "first"), link(4, "prev"), link(5, "last")}},
+		{"past the end", 9, 45, []string{link(1, "first"), link(5, "prev"), link(5, "last")}},
+		{"empty", 1, 0, []string{link(1, "first"), link(1, "last")}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, w := newPaginationContext("/api/v1/users?page=" + strconv.Itoa(tc.page) + "&page_size=10")
+			p := pagination{Page: tc.page, PageSize: 10, Total: tc.total}
+
+			setPaginationHeaders(c, p)
+
+			if got, want := w.Header().Get("Link"), strings.Join(tc.links, ", "); got != want {
+				t.Errorf("Link:\n got  %s\n want %s", got, want)
+			}
+			if got, want := w.Header().Get("X-Total-Count"), strconv.Itoa(int(tc.total)); got != want {
+				t.Errorf("X-Total-Count = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestPaginationPastEnd(t *testing.T) {
+	if !(pagination{Page: 6, PageSize: 10, Total: 45}).pastEnd() {
+		t.Error("page 6 of 5 should be past the end")
+	}
+	if (pagination{Page: 1, PageSize: 10, Total: 0}).pastEnd() {
+		t.Error("page 1 of an empty table should not be past the end")
+	}
+}
+
+func TestParsePaginationBounds(t *testing.T) {
+	cases := []struct {
+		query   string
+		paged   bool
+		wantErr bool
+	}{
+		{"", false, false},
+		{"page=2", true, false},
+		{"page=0", true, true},
+		{"page=" + strconv.Itoa(maxPage), true, false},
+		{"page=" + strconv.Itoa(maxPage+1), true, true},
+		{"page=9223372036854775807", true, true},
+		{"page_size=0", true, true},
+		{"page_size=" + strconv.Itoa(maxPageSize+1), true, true},
+	}
+
+	for _, tc := range cases {
+		c, _ := newPaginationContext("/api/v1/users?" + tc.query)
+		_, paged, err := parsePagination(c)
+		if paged != tc.paged || (err != nil) != tc.wantErr {
+			t.Errorf("%q: paged=%v err=%v, want paged=%v wantErr=%v", tc.query, paged, err, tc.paged, tc.wantErr)
+		}
+	}
+}
+
+func TestPageLinkForwardedProto(t *testing.T) {
+	cases := []struct {
+		proto string
+		tls   bool
+		want  string
+	}{
+		{"", false, "http"},
+		{"", true, "https"},
+		{"https", false, "https"},
+		{"HTTPS", false, "https"},
+		{"http", true, "http"},
+		{"https, http", false, "https"},
+		{" http ,https", true, "http"},
+		{"javascript", false, "http"},
+		{"ftp, https", true, "https"},
+	}
+
+	for _, tc := range cases {
+		c, _ := newPaginationContext("/api/v1/users?page=1")
+		c.Request.Header.Set("X-Forwarded-Proto", tc.proto)
+		if tc.tls {
+			c.Request.TLS = &tls.ConnectionState{}
+		}
+
+		link := pageLink(c, 2, 10, "next")
+		want := "<" + tc.want + "://example.com/api/v1/users?page=2&page_size=10>"
+		if !strings.HasPrefix(link, want) {
+			t.Errorf("X-Forwarded-Proto %q (tls=%v): link = %s, want prefix %s", tc.proto, tc.tls, link, want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 统一的JSON响应渲染：
+//   - 字段命名风格由 JSON_FIELD_CASE 控制（见 fieldcase.go），默认与结构体 json tag 一致的 snake_case
+//   - 请求携带 ?pretty=true 时缩进输出
+
+const prettyIndent = "    "
+
+// renderJSON 按统一的命名风格和缩进配置输出JSON响应
+// 请求命中废弃接口时，在 gin.H 信封中附加 warning 字段
+func renderJSON(c *gin.Context, code int, obj any) {
+	if warning := deprecationWarning(c); warning != "" {
+		if h, ok := obj.(gin.H); ok {
+			if _, exists := h["warning"]; !exists {
+				h["warning"] = warning
+			}
+		}
+	}
+
+	data, err := renderValue(obj, wantPretty(c), "")
+	if err != nil {
+		fmt.Printf("render json failed: %v\n", err)
+		c.Data(http.StatusInternalServerError, "application/json; charset=utf-8", []byte(`{"error":"render response failed"}`))
+		return
+	}
+	c.Data(code, "application/json; charset=utf-8", data)
+}
+
+// abortJSON 渲染JSON响应并终止后续handler
+func abortJSON(c *gin.Context, code int, obj any) {
+	c.Abort()
+	renderJSON(c, code, obj)
+}
+
+// wantPretty 请求是否要求缩进输出
+func wantPretty(c *gin.Context) bool {
+	pretty, _ := strconv.ParseBool(c.Query("pretty"))
+	return pretty
+}
+
+// renderValue 序列化单个值：转换字段命名，pretty 时以 prefix 为行首缩进
+// 流式输出时逐条调用，保证与 renderJSON 的输出格式一致
+func renderValue(v any, pretty bool, prefix string) ([]byte, error) {
+	data, err := jsonAPI.Marshal(applyFieldCase(v))
+	if err != nil {
+		return nil, err
+	}
+
+	if pretty {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, prefix, prettyIndent); err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+	}
+	return data, nil
+}
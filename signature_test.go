@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testHMACSecret = "test-secret"
+
+// newSignatureRouter 使用 miniredis 作为 nonce 存储，handler 回显请求体以确认其被正确放回
+func newSignatureRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("HMAC_SECRET", testHMACSecret)
+
+	useTestRedis(t)
+
+	r := gin.New()
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	}
+	api := r.Group("/api/v1/users", signatureAuth())
+	api.POST("", echo)
+	api.GET("/:id", echo)
+	return r
+}
+
+type signedRequest struct {
+	method    string
+	target    string
+	body      string
+	timestamp time.Time
+	nonce     string
+}
+
+func validSignedRequest() signedRequest {
+	return signedRequest{
+		method:    http.MethodPost,
+		target:    "/api/v1/users?source=test",
+		body:      `{"name":"gin","email":"gin@example.com"}`,
+		timestamp: time.Now(),
+		nonce:     "0123456789abcdef",
+	}
+}
+
+// build 按服务端算法签名；sentBody 非空时发送与签名不同的请求体
+func (s signedRequest) build(sentBody string) *http.Request {
+	ts := strconv.FormatInt(s.timestamp.Unix(), 10)
+	sig := signRequest([]byte(testHMACSecret), s.method, s.target, ts, s.nonce, []byte(s.body))
+
+	if sentBody == "" {
+		sentBody = s.body
+	}
+	req := httptest.NewRequest(s.method, s.target, strings.NewReader(sentBody))
+	req.Header.Set(signatureTimestampHeader, ts)
+	req.Header.Set(signatureNonceHeader, s.nonce)
+	req.Header.Set(signatureHeader, hex.EncodeToString(sig))
+	return req
+}
+
+func TestSignatureValid(t *testing.T) {
+	r := newSignatureRouter(t)
+	s := validSignedRequest()
+
+	w := serve(r, s.build(""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if w.Body.String() != s.body {
+		t.Errorf("handler body = %q, want %q", w.Body.String(), s.body)
+	}
+}
+
+func TestSignatureTamperedBody(t *testing.T) {
+	r := newSignatureRouter(t)
+	s := validSignedRequest()
+
+	w := serve(r, s.build(`{"name":"evil","email":"evil@example.com"}`))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSignatureTimestampSkew(t *testing.T) {
+	cases := []struct {
+		name   string
+		offset time.Duration
+		want   int
+	}{
+		{"within past window", -4 * time.Minute, http.StatusOK},
+		{"within future window", 4 * time.Minute, http.StatusOK},
+		{"too old", -6 * time.Minute, http.StatusUnauthorized},
+		{"too far in future", 6 * time.Minute, http.StatusUnauthorized},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newSignatureRouter(t)
+			s := validSignedRequest()
+			s.timestamp = time.Now().Add(tc.offset)
+			s.nonce = "skew-nonce-" + strconv.Itoa(i) + "-padding"
+
+			if w := serve(r, s.build("")); w.Code != tc.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tc.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSignatureNonceLength(t *testing.T) {
+	cases := []struct {
+		length int
+		want   int
+	}{
+		{nonceMinLen - 1, http.StatusUnauthorized},
+		{nonceMinLen, http.StatusOK},
+		{nonceMaxLen, http.StatusOK},
+		{nonceMaxLen + 1, http.StatusUnauthorized},
+	}
+
+	for _, tc := range cases {
+		t.Run(strconv.Itoa(tc.length), func(t *testing.T) {
+			r := newSignatureRouter(t)
+			s := validSignedRequest()
+			s.nonce = strings.Repeat("n", tc.length)
+
+			if w := serve(r, s.build("")); w.Code != tc.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tc.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSignatureReplay(t *testing.T) {
+	r := newSignatureRouter(t)
+	s := validSignedRequest()
+
+	if w := serve(r, s.build("")); w.Code != http.StatusOK {
+		t.Fatalf("first request status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	w := serve(r, s.build(""))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("replayed request status = %d, want 401: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "replayed request") {
+		t.Errorf("replayed request body = %s", w.Body.String())
+	}
+}
+
+func TestSignatureBodyTooLarge(t *testing.T) {
+	r := newSignatureRouter(t)
+	s := validSignedRequest()
+	s.body = string(bytes.Repeat([]byte("x"), signatureMaxBody+1))
+
+	if w := serve(r, s.build("")); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSignatureMissingHeaders(t *testing.T) {
+	r := newSignatureRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(`{}`))
+	if w := serve(r, req); w.Code != http.StatusUnauthorized {
+		t.Fatalf("unsigned POST status = %d, want 401", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+	if w := serve(r, req); w.Code != http.StatusOK {
+		t.Fatalf("unsigned GET status = %d, want 200", w.Code)
+	}
+}
+
+func TestSignRequestCoversEveryField(t *testing.T) {
+	secret := []byte(testHMACSecret)
+	base := signRequest(secret, "POST", "/api/v1/users", "1700000000", "0123456789abcdef", []byte("{}"))
+
+	variants := map[string][]byte{
+		"secret":    signRequest([]byte("other"), "POST", "/api/v1/users", "1700000000", "0123456789abcdef", []byte("{}")),
+		"method":    signRequest(secret, "PUT", "/api/v1/users", "1700000000", "0123456789abcdef", []byte("{}")),
+		"uri":       signRequest(secret, "POST", "/api/v1/users?x=1", "1700000000", "0123456789abcdef", []byte("{}")),
+		"timestamp": signRequest(secret, "POST", "/api/v1/users", "1700000001", "0123456789abcdef", []byte("{}")),
+		"nonce":     signRequest(secret, "POST", "/api/v1/users", "1700000000", "0123456789abcdeg", []byte("{}")),
+		"body":      signRequest(secret, "POST", "/api/v1/users", "1700000000", "0123456789abcdef", []byte("[]")),
+	}
+	for field, sig := range variants {
+		if bytes.Equal(sig, base) {
+			t.Errorf("changing %s did not change the signature", field)
+		}
+	}
+
+	again := signRequest(secret, "POST", "/api/v1/users", "1700000000", "0123456789abcdef", []byte("{}"))
+	if !bytes.Equal(again, base) {
+		t.Error("signRequest is not deterministic")
+	}
+}
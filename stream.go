@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// userStreamBatchSize 流式输出时每批从MySQL读取的行数
+const userStreamBatchSize = 500
+
+// streamUsers 按主键分批读取全部用户并逐条回调，每批结束后刷新响应缓冲区
+// 同一批次复用切片，内存占用与总行数无关
+func streamUsers(c *gin.Context, fn func(u *User) error) error {
+	var batch []User
+	return db.WithContext(c.Request.Context()).FindInBatches(&batch, userStreamBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+		if c.Writer.Written() {
+			c.Writer.Flush()
+		}
+		return nil
+	}).Error
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 包含引号、百分号、换行等需要转义的字符，确保提示原样进入JSON
+const testWarning = "列表接口已废弃 \"100%\" %d\n请改用分页"
+
+// newListRouter listUsers 前可选地注入废弃提示，模拟 deprecated 中间件
+func newListRouter(warning string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/v1/users", func(c *gin.Context) {
+		if warning != "" {
+			c.Set(deprecationWarningKey, warning)
+		}
+	}, listUsers)
+	r.GET("/api/v1/users/export", exportUsers)
+	return r
+}
+
+type listEnvelope struct {
+	Data    []User  `json:"data"`
+	Count   int     `json:"count"`
+	Warning *string `json:"warning"`
+}
+
+func TestListUsersStream(t *testing.T) {
+	for _, rows := range []int{0, 1, 3, userStreamBatchSize + 1} {
+		for _, pretty := range []bool{false, true} {
+			for _, warning := range []string{"", testWarning} {
+				name := "rows=" + strconv.Itoa(rows) + "/pretty=" + strconv.FormatBool(pretty) + "/warning=" + strconv.FormatBool(warning != "")
+				t.Run(name, func(t *testing.T) {
+					useTestDB(t, rows)
+					r := newListRouter(warning)
+
+					w := serve(r, httptest.NewRequest(http.MethodGet, "/api/v1/users?pretty="+strconv.FormatBool(pretty), nil))
+					if w.Code != http.StatusOK {
+						t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+					}
+					if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+						t.Errorf("Content-Type = %q", ct)
+					}
+					body := w.Body.Bytes()
+					checkJSONLayout(t, body, pretty)
+
+					var got listEnvelope
+					dec := json.NewDecoder(bytes.NewReader(body))
+					dec.DisallowUnknownFields()
+					if err := dec.Decode(&got); err != nil {
+						t.Fatalf("decode: %v\n%s", err, body)
+					}
+
+					if got.Count != rows || len(got.Data) != rows {
+						t.Fatalf("count = %d, len(data) = %d, want %d", got.Count, len(got.Data), rows)
+					}
+					if got.Data == nil {
+						t.Error("data should be an empty array, not null")
+					}
+					for i, u := range got.Data {
+						if u.ID != i+1 || u.Name != "user-"+strconv.Itoa(i+1) {
+							t.Fatalf("data[%d] = %+v", i, u)
+						}
+					}
+
+					switch {
+					case warning == "" && got.Warning != nil:
+						t.Errorf("unexpected warning %q", *got.Warning)
+					case warning != "" && (got.Warning == nil || *got.Warning != warning):
+						t.Errorf("warning = %v, want %q", got.Warning, warning)
+					}
+				})
+			}
+		}
+	}
+}
+
+// checkJSONLayout 输出必须是合法JSON，且 pretty 时与标准缩进一致、否则为紧凑格式
+func checkJSONLayout(t *testing.T, body []byte, pretty bool) {
+	t.Helper()
+
+	if !json.Valid(body) {
+		t.Fatalf("invalid JSON:\n%s", body)
+	}
+
+	var compact, want bytes.Buffer
+	if err := json.Compact(&compact, body); err != nil {
+		t.Fatal(err)
+	}
+	if pretty {
+		if err := json.Indent(&want, compact.Bytes(), "", prettyIndent); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		want = compact
+	}
+	if !bytes.Equal(body, want.Bytes()) {
+		t.Errorf("layout mismatch (pretty=%v):\n got  %s\n want %s", pretty, body, want.Bytes())
+	}
+}
+
+func TestExportUsers(t *testing.T) {
+	for _, rows := range []int{0, 1, userStreamBatchSize + 1} {
+		t.Run(strconv.Itoa(rows), func(t *testing.T) {
+			useTestDB(t, rows)
+			r := newListRouter("")
+
+			// pretty 对 NDJSON 不生效
+			w := serve(r, httptest.NewRequest(http.MethodGet, "/api/v1/users/export?pretty=true", nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/x-ndjson") {
+				t.Errorf("Content-Type = %q", ct)
+			}
+
+			lines := 0
+			scanner := bufio.NewScanner(w.Body)
+			for scanner.Scan() {
+				var u User
+				if err := json.Unmarshal(scanner.Bytes(), &u); err != nil {
+					t.Fatalf("line %d: %v: %s", lines+1, err, scanner.Bytes())
+				}
+				lines++
+				if u.ID != lines {
+					t.Fatalf("line %d id = %d", lines, u.ID)
+				}
+			}
+			if lines != rows {
+				t.Errorf("lines = %d, want %d", lines, rows)
+			}
+		})
+	}
+}